@@ -0,0 +1,354 @@
+// Command varmockgen generates the function-variable mocking scaffolding
+// demonstrated in demo_mocks from a plain struct, so callers stop
+// hand-writing the "-Impl" field, constructor wiring, and "Instrumented"
+// trampoline for every method.
+//
+// Usage:
+//
+//	varmockgen -type=Foo input.go
+//
+// Given a struct Foo with ordinary methods, it writes input_varmock.go
+// containing:
+//   - a refactored Foo whose methods are replaced by <method>FuncVar fields
+//     (e.g. greetFuncVar for Greet) and <Method>Impl methods
+//   - a NewFoo constructor wiring each field to its -Impl by default
+//   - a FooMock wrapping *Foo and mock.Mock, with a <Method>Instrumented
+//     trampoline per method ready to assign to the matching field
+//
+// The emitted -Impl method bodies are stubs that panic: varmockgen moves the
+// method signatures, not their logic, so the file compiles as generated but
+// the original bodies still need to be copied in by hand before the -Impl
+// methods do any real work.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+func main() {
+	var (
+		typeName        = flag.String("type", "", "struct type to generate function-variable mocking scaffolding for")
+		withPassthrough = flag.Bool("with-passthrough", false, "also generate a <Method>InstrumentedWithOrigCallTracking variant per method")
+		callRecorder    = flag.Bool("call-recorder", false, "emit a per-method Calls() slice for inspection without testify/mock")
+		output          = flag.String("output", "", "output file (default: <input>_varmock.go)")
+	)
+	flag.Parse()
+
+	if *typeName == "" || flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: varmockgen -type=Foo <file.go>")
+		os.Exit(2)
+	}
+
+	src := flag.Arg(0)
+	out := *output
+	if out == "" {
+		out = strings.TrimSuffix(src, ".go") + "_varmock.go"
+	}
+
+	n, err := generate(src, *typeName, out, *withPassthrough, *callRecorder)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "varmockgen:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("varmockgen: wrote %s (%d method(s))\n", out, n)
+}
+
+// generate parses typeName's methods out of src and writes the generated
+// scaffolding to out, returning the number of methods found.
+func generate(src, typeName, out string, withPassthrough, callRecorder bool) (int, error) {
+	methods, pkgName, err := loadMethods(src, typeName)
+	if err != nil {
+		return 0, err
+	}
+	if len(methods) == 0 {
+		return 0, fmt.Errorf("no methods found on %s in %s", typeName, src)
+	}
+
+	data := genData{
+		Package:         pkgName,
+		Source:          src,
+		Type:            typeName,
+		Methods:         methods,
+		WithPassthrough: withPassthrough,
+		CallRecorder:    callRecorder,
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return 0, err
+	}
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		// write the unformatted source anyway so it can be fixed up by hand
+		formatted = buf.Bytes()
+	}
+	if err := os.WriteFile(out, formatted, 0o644); err != nil {
+		return 0, err
+	}
+	return len(methods), nil
+}
+
+type method struct {
+	Name        string
+	FuncVarName string
+	FuncVarType string // "func(string) string"
+	ParamList   string // "input string"
+	ParamNames  string // "input"
+	Results     []string
+	ResultList  string // "" | "string" | "(string, error)"
+}
+
+type genData struct {
+	Package         string
+	Source          string
+	Type            string
+	Methods         []method
+	WithPassthrough bool
+	CallRecorder    bool
+}
+
+// loadMethods parses src and returns every method declared with a pointer
+// receiver of typeName, in source order.
+func loadMethods(src, typeName string) ([]method, string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, src, nil, parser.ParseComments)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var methods []method
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || len(fn.Recv.List) != 1 {
+			continue
+		}
+		recvType := fn.Recv.List[0].Type
+		star, ok := recvType.(*ast.StarExpr)
+		if !ok {
+			continue
+		}
+		ident, ok := star.X.(*ast.Ident)
+		if !ok || ident.Name != typeName {
+			continue
+		}
+
+		m := method{Name: fn.Name.Name, FuncVarName: funcVarName(fn.Name.Name)}
+
+		var paramDecls, paramNames, funcVarParams []string
+		for _, p := range fn.Type.Params.List {
+			typ := exprString(fset, p.Type)
+			names := p.Names
+			if len(names) == 0 {
+				names = []*ast.Ident{{Name: "_"}}
+			}
+			for _, n := range names {
+				paramDecls = append(paramDecls, n.Name+" "+typ)
+				paramNames = append(paramNames, n.Name)
+				funcVarParams = append(funcVarParams, typ)
+			}
+		}
+		m.ParamList = strings.Join(paramDecls, ", ")
+		m.ParamNames = strings.Join(paramNames, ", ")
+
+		var resultTypes []string
+		if fn.Type.Results != nil {
+			for _, r := range fn.Type.Results.List {
+				typ := exprString(fset, r.Type)
+				n := len(r.Names)
+				if n == 0 {
+					n = 1
+				}
+				for i := 0; i < n; i++ {
+					resultTypes = append(resultTypes, typ)
+				}
+			}
+		}
+		m.Results = resultTypes
+		switch len(resultTypes) {
+		case 0:
+			m.ResultList = ""
+		case 1:
+			m.ResultList = resultTypes[0]
+		default:
+			m.ResultList = "(" + strings.Join(resultTypes, ", ") + ")"
+		}
+		m.FuncVarType = strings.TrimSpace(fmt.Sprintf("func(%s) %s", strings.Join(funcVarParams, ", "), m.ResultList))
+
+		methods = append(methods, m)
+	}
+
+	return methods, file.Name.Name, nil
+}
+
+func funcVarName(method string) string {
+	r := []rune(method)
+	r[0] = unicode.ToLower(r[0])
+	return string(r) + "FuncVar"
+}
+
+func exprString(fset *token.FileSet, expr ast.Expr) string {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, expr); err != nil {
+		return fmt.Sprintf("%v", expr)
+	}
+	return buf.String()
+}
+
+// captureOrig renders the call-through to typeName's -Impl for the
+// --with-passthrough variant, binding its return values (if any) into
+// origReturns so the caller can append them to <Method>CapturedReturns --
+// the generated analogue of demo_mocks_test.go's hand-written
+// myMethod1InstrumentedWithOrigCallTracking, which captures into
+// capturedReturns rather than discarding the original call's result.
+func captureOrig(typeName string, m method) string {
+	call := fmt.Sprintf("m.%s.%sImpl(%s)", typeName, m.Name, m.ParamNames)
+
+	switch len(m.Results) {
+	case 0:
+		return fmt.Sprintf("%s\n\torigReturns := []interface{}{}", call)
+	case 1:
+		return fmt.Sprintf("orig0 := %s\n\torigReturns := []interface{}{orig0}", call)
+	default:
+		var names []string
+		for i := range m.Results {
+			names = append(names, fmt.Sprintf("orig%d", i))
+		}
+		return fmt.Sprintf("%s := %s\n\torigReturns := []interface{}{%s}",
+			strings.Join(names, ", "), call, strings.Join(names, ", "))
+	}
+}
+
+// ReturnExtraction renders the body of an Instrumented trampoline: the
+// args.Get(i).(Type) type assertions testify/mock needs since it boxes
+// return values as interface{}.
+func (m method) ReturnExtraction() string {
+	switch len(m.Results) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("return args.Get(0).(%s)", m.Results[0])
+	default:
+		var lines []string
+		var names []string
+		for i, t := range m.Results {
+			name := fmt.Sprintf("r%d", i)
+			lines = append(lines, fmt.Sprintf("%s := args.Get(%d).(%s)", name, i, t))
+			names = append(names, name)
+		}
+		lines = append(lines, "return "+strings.Join(names, ", "))
+		return strings.Join(lines, "\n")
+	}
+}
+
+var tmpl = template.Must(template.New("varmock").Funcs(template.FuncMap{
+	"captureOrig": captureOrig,
+}).Parse(`// Code generated by varmockgen from {{.Source}}; DO NOT EDIT.
+
+package {{.Package}}
+
+import "github.com/stretchr/testify/mock"
+
+// {{.Type}} was generated from the struct of the same name in {{.Source}}.
+// Each method became a func-var field plus a <Method>Impl; -Impl bodies are
+// stubs here and need the original method logic copied in by hand.
+type {{.Type}} struct {
+{{- range .Methods}}
+	{{.FuncVarName}} {{.FuncVarType}}
+{{- end}}
+}
+
+{{range .Methods}}
+func (s *{{$.Type}}) {{.Name}}Impl({{.ParamList}}) {{.ResultList}} {
+	panic("varmockgen: {{.Name}}Impl body is not generated; copy the original method body here")
+}
+{{end}}
+// New{{.Type}} wires every func-var field to its -Impl by default; override
+// a field in tests to mock that method.
+func New{{.Type}}() *{{.Type}} {
+	newService := &{{.Type}}{}
+{{- range .Methods}}
+	newService.{{.FuncVarName}} = newService.{{.Name}}Impl
+{{- end}}
+	return newService
+}
+
+// {{.Type}}Mock wraps *{{.Type}} with testify/mock; assign a field to the
+// matching <Method>Instrumented trampoline to drive it through m.On(...).
+type {{.Type}}Mock struct {
+	*{{.Type}}
+	mock.Mock
+{{- if .CallRecorder}}
+
+	calls map[string][]Call
+{{- end}}
+{{- if .WithPassthrough}}
+{{- range .Methods}}
+	{{.Name}}CapturedReturns [][]interface{}
+{{- end}}
+{{- end}}
+}
+{{- if .CallRecorder}}
+
+// Call records a single invocation for inspection without testify/mock.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// Calls returns every recorded invocation of method, in call order.
+func (m *{{.Type}}Mock) Calls(method string) []Call {
+	return m.calls[method]
+}
+
+func (m *{{.Type}}Mock) record(method string, args ...interface{}) {
+	if m.calls == nil {
+		m.calls = map[string][]Call{}
+	}
+	m.calls[method] = append(m.calls[method], Call{Method: method, Args: args})
+}
+{{- end}}
+
+{{range .Methods}}
+func (m *{{$.Type}}Mock) {{.Name}}Instrumented({{.ParamList}}) {{.ResultList}} {
+{{- if $.CallRecorder}}
+	m.record("{{.Name}}", {{.ParamNames}})
+{{- end}}
+{{- if .Results}}
+	args := m.Called({{.ParamNames}})
+	{{.ReturnExtraction}}
+{{- else}}
+	m.Called({{.ParamNames}})
+{{- end}}
+}
+{{if $.WithPassthrough}}
+// {{.Name}}InstrumentedWithOrigCallTracking also calls through to the
+// original {{.Name}}Impl, capturing its return value(s) into
+// {{.Name}}CapturedReturns, so integration-style tests can observe real
+// side effects alongside the testify/mock expectation.
+func (m *{{$.Type}}Mock) {{.Name}}InstrumentedWithOrigCallTracking({{.ParamList}}) {{.ResultList}} {
+{{- if $.CallRecorder}}
+	m.record("{{.Name}}InstrumentedWithOrigCallTracking", {{.ParamNames}})
+{{- end}}
+	{{captureOrig $.Type .}}
+	m.{{.Name}}CapturedReturns = append(m.{{.Name}}CapturedReturns, origReturns)
+{{- if .Results}}
+	args := m.Called({{.ParamNames}})
+	{{.ReturnExtraction}}
+{{- else}}
+	m.Called({{.ParamNames}})
+{{- end}}
+}
+{{end}}
+{{end}}
+`))
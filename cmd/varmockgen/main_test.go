@@ -0,0 +1,154 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const fixture = `package demo
+
+type Greeter struct{}
+
+func (g *Greeter) Greet(name string) string {
+	return "hello " + name
+}
+
+func (g *Greeter) Reset() {
+}
+
+func (g *Greeter) Lookup(id int) (string, error) {
+	return "", nil
+}
+`
+
+func TestLoadMethods(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "greeter.go")
+	assert.NoError(t, os.WriteFile(src, []byte(fixture), 0o644))
+
+	methods, pkgName, err := loadMethods(src, "Greeter")
+	assert.NoError(t, err)
+	assert.Equal(t, "demo", pkgName)
+	assert.Len(t, methods, 3)
+
+	assert.Equal(t, "Greet", methods[0].Name)
+	assert.Equal(t, "greetFuncVar", methods[0].FuncVarName)
+	assert.Equal(t, "func(string) string", methods[0].FuncVarType)
+	assert.Equal(t, "return args.Get(0).(string)", methods[0].ReturnExtraction())
+
+	assert.Equal(t, "Reset", methods[1].Name)
+	assert.Equal(t, "func()", methods[1].FuncVarType)
+	assert.Equal(t, "", methods[1].ReturnExtraction())
+
+	assert.Equal(t, "Lookup", methods[2].Name)
+	assert.Equal(t, "func(int) (string, error)", methods[2].FuncVarType)
+	assert.Contains(t, methods[2].ReturnExtraction(), "r0 := args.Get(0).(string)")
+}
+
+// TestGenerateCompiles runs the generator end-to-end and compiles the
+// result, in its own module, so a template bug that only breaks `go build`
+// (e.g. an unused `args` for a zero-return method like Reset) gets caught
+// here instead of only in loadMethods/ReturnExtraction's string assertions.
+func TestGenerateCompiles(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	// The generated file is a replacement for the struct it was read from,
+	// not a companion to it, so it's parsed from one directory but built
+	// alone in another -- building them together would redeclare Greeter.
+	srcDir := t.TempDir()
+	src := filepath.Join(srcDir, "greeter.go")
+	assert.NoError(t, os.WriteFile(src, []byte(fixture), 0o644))
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "greeter_varmock.go")
+	n, err := generate(src, "Greeter", out, true, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	run := func(args ...string) {
+		cmd := exec.Command(goBin, args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+		output, err := cmd.CombinedOutput()
+		assert.NoErrorf(t, err, "%s: %s", args, output)
+	}
+
+	run("mod", "init", "generatedtest")
+	run("mod", "tidy")
+	run("build", "./...")
+}
+
+// TestGenerateWithPassthroughCapturesOriginalReturn runs the generated
+// <Method>InstrumentedWithOrigCallTracking end-to-end and asserts on the
+// actual value it captured from the real -Impl, not just that the generated
+// code compiles: a template bug that silently discards the -Impl's return
+// (rather than failing to build) would otherwise go unnoticed.
+func TestGenerateWithPassthroughCapturesOriginalReturn(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	srcDir := t.TempDir()
+	src := filepath.Join(srcDir, "greeter.go")
+	assert.NoError(t, os.WriteFile(src, []byte(fixture), 0o644))
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "greeter_varmock.go")
+	n, err := generate(src, "Greeter", out, true, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	// The generated GreetImpl is a panic stub (varmockgen only moves method
+	// signatures, not bodies); swap in real logic so the call-through under
+	// test has something real to capture.
+	generated, err := os.ReadFile(out)
+	assert.NoError(t, err)
+	patched := strings.Replace(string(generated),
+		`panic("varmockgen: GreetImpl body is not generated; copy the original method body here")`,
+		`return "captured " + name`, 1)
+	assert.NoError(t, os.WriteFile(out, []byte(patched), 0o644))
+
+	const harness = `package demo
+
+import "testing"
+
+func TestCapture(t *testing.T) {
+	m := &GreeterMock{Greeter: NewGreeter()}
+	m.On("GreetInstrumentedWithOrigCallTracking", "world").Return("mocked").Once()
+
+	got := m.GreetInstrumentedWithOrigCallTracking("world")
+	if got != "mocked" {
+		t.Fatalf("got %q, want %q", got, "mocked")
+	}
+
+	if len(m.GreetCapturedReturns) != 1 {
+		t.Fatalf("GreetCapturedReturns = %v, want 1 entry", m.GreetCapturedReturns)
+	}
+	if want := []interface{}{"captured world"}; m.GreetCapturedReturns[0][0] != want[0] {
+		t.Fatalf("GreetCapturedReturns[0] = %v, want %v", m.GreetCapturedReturns[0], want)
+	}
+}
+`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "harness_test.go"), []byte(harness), 0o644))
+
+	run := func(args ...string) {
+		cmd := exec.Command(goBin, args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+		output, err := cmd.CombinedOutput()
+		assert.NoErrorf(t, err, "%s: %s", args, output)
+	}
+
+	run("mod", "init", "generatedtest")
+	run("mod", "tidy")
+	run("test", "./...")
+}
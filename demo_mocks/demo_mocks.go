@@ -9,8 +9,10 @@ import "fmt"
 // this seems like a strange abstraction layer at first,
 // but its use will be apparent soon.
 type Service struct {
-	myFuncVar1 func(string) string
-	myFuncVar2 func()
+	myFuncVar1     func(string) string
+	myFuncVar2     func()
+	myFuncVarAsync func()
+	myFuncVar3     func() <-chan error
 }
 
 // -Impl suffix means the actual func implementation for normal program execution
@@ -24,6 +26,25 @@ func (s *Service) myMethod2Impl() {
 	s.myFuncVar1("Method 1 called from Method 2")
 }
 
+// myMethod2AsyncImpl calls myFuncVar1 from a goroutine instead of the call
+// stack, the way a real method might once it offloads work. A synchronous
+// assertion right after AsyncProgramFlow would race this call; see
+// varmock.Dispatcher.WaitFor for how tests synchronize with it instead.
+func (s *Service) myMethod2AsyncImpl() {
+	go s.myFuncVar1("Method 1 called from Method 2, asynchronously")
+}
+
+// myMethod3Impl streams a sequence of results on a channel, for methods that
+// hand the caller a <-chan error instead of returning synchronously.
+func (s *Service) myMethod3Impl() <-chan error {
+	ch := make(chan error)
+	go func() {
+		defer close(ch)
+		ch <- nil
+	}()
+	return ch
+}
+
 // Step 2: assign implementations to struct's function variables for normal operation.
 // we use this constructor in non-test code.
 func NewService() *Service {
@@ -33,6 +54,8 @@ func NewService() *Service {
 	// default, but these fields can be overridden as needed in tests.
 	newService.myFuncVar1 = newService.myMethod1Impl
 	newService.myFuncVar2 = newService.myMethod2Impl
+	newService.myFuncVarAsync = newService.myMethod2AsyncImpl
+	newService.myFuncVar3 = newService.myMethod3Impl
 	return newService
 }
 
@@ -41,3 +64,20 @@ func NormalProgramFlow(myService *Service) {
 	myService.myFuncVar1("first call") // myMethodImpl in normal code flow
 	myService.myFuncVar2()             // myMethod2Impl in normal code flow
 }
+
+// AsyncProgramFlow exercises myFuncVarAsync, whose implementation calls
+// myFuncVar1 from a goroutine.
+func AsyncProgramFlow(myService *Service) {
+	myService.myFuncVarAsync()
+}
+
+// ChannelProgramFlow drains myFuncVar3 and returns the first non-nil error
+// received, if any.
+func ChannelProgramFlow(myService *Service) error {
+	for err := range myService.myFuncVar3() {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
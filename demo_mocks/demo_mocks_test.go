@@ -2,9 +2,10 @@ package demo_mocks
 
 import (
 	"testing"
+	"time"
 
+	"github.com/joecompute/go-variable-method-mocking/varmock"
 	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/mock"
 )
 
 // Benefit: testing without need for more heavyweight interfaces
@@ -12,7 +13,7 @@ import (
 
 type ServiceMock struct {
 	*Service
-	mock.Mock
+	varmock.Dispatcher
 	count           int
 	capturedReturns []string
 }
@@ -87,3 +88,156 @@ func TestWTestifyMockPassthrough(t *testing.T) {
 	assert.Len(t, wrappedWithMock.capturedReturns, 2)
 	wrappedWithMock.AssertExpectations(t)
 }
+
+// SCENARIO 4: instead of a string method name, register directly against the
+// function-variable field itself (renames of myMethod1Impl are then caught
+// by the compiler), and compute the return value from the arguments.
+func TestOnFuncWhenArgsReturnFn(t *testing.T) {
+	wrappedWithMock := ServiceMock{Service: NewService()}
+
+	wrappedWithMock.OnFunc(&wrappedWithMock.myFuncVar1).
+		WhenArgs(func(input string) bool { return input == "first call" }).
+		ReturnFn(func(input string) string {
+			return "dynamic: " + input
+		}).Once()
+
+	NormalProgramFlow(wrappedWithMock.Service)
+
+	// "Method 1 called from Method 2" didn't match the matcher above, so it
+	// fell through to myMethod1Impl instead of panicking on an unmatched call.
+	wrappedWithMock.AssertExpectations(t)
+}
+
+// TestOnFuncTwoMatchersOneField registers two separate matchers against the
+// same function-variable field -- the only way to do so, since ReturnFn
+// returns a *mock.Call rather than a *Stub and so can't be chained a second
+// time off one OnFunc call -- and checks that a single unmatched call is
+// still counted once, not once per registration (a regression test for
+// OnFunc re-wrapping the field on its second call and capturing the
+// already-installed trampoline as "original" instead of the real -Impl).
+func TestOnFuncTwoMatchersOneField(t *testing.T) {
+	wrappedWithMock := ServiceMock{Service: NewService()}
+
+	wrappedWithMock.OnFunc(&wrappedWithMock.myFuncVar1).
+		WhenArgs(func(input string) bool { return input == "never matches a" }).
+		ReturnFn(func(input string) string { return "a: " + input })
+
+	wrappedWithMock.OnFunc(&wrappedWithMock.myFuncVar1).
+		WhenArgs(func(input string) bool { return input == "never matches b" }).
+		ReturnFn(func(input string) string { return "b: " + input })
+
+	// Neither matcher matches, so both calls fall through to myMethod1Impl.
+	NormalProgramFlow(wrappedWithMock.Service)
+
+	err := wrappedWithMock.WaitFor(&wrappedWithMock.myFuncVar1, 2, time.Second)
+	assert.NoError(t, err)
+
+	// If OnFunc had nested a second trampoline around the first, this single
+	// extra call would additionally satisfy a 3rd/4th expected count.
+	err = wrappedWithMock.WaitFor(&wrappedWithMock.myFuncVar1, 3, 50*time.Millisecond)
+	assert.Error(t, err)
+}
+
+// myMethod2AsyncImpl calls myFuncVar1 from a goroutine, so asserting right
+// after AsyncProgramFlow returns would race the call; WaitFor blocks until
+// it has actually happened.
+func TestWaitForAsyncCall(t *testing.T) {
+	wrappedWithMock := ServiceMock{Service: NewService()}
+
+	wrappedWithMock.OnFunc(&wrappedWithMock.myFuncVar1).ReturnFn(func(input string) string {
+		wrappedWithMock.count++
+		return input
+	})
+
+	AsyncProgramFlow(wrappedWithMock.Service)
+
+	err := wrappedWithMock.WaitFor(&wrappedWithMock.myFuncVar1, 1, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, wrappedWithMock.count)
+
+	// WaitFor already guarantees the call finished, so Wg.Wait() here
+	// returns immediately; it's exercised directly so regressions in the
+	// transparent Add(1)/Done() bookkeeping fail the test after a second
+	// instead of passing silently.
+	done := make(chan struct{})
+	go func() {
+		wrappedWithMock.Wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Wg.Wait() did not return once the async call completed")
+	}
+}
+
+// SCENARIO 5: a Spy records every call transparently, no testify/mock
+// expectations or ad-hoc count/capturedReturns fields required.
+func TestSpy(t *testing.T) {
+	wrappedWithMock := ServiceMock{Service: NewService()}
+
+	spy := varmock.NewSpy(wrappedWithMock.myMethod1Impl)
+	wrappedWithMock.myFuncVar1 = spy.Fn()
+
+	NormalProgramFlow(wrappedWithMock.Service)
+
+	assert.Equal(t, 2, spy.CallCount())
+	assert.True(t, spy.CalledWith("first call"))
+	assert.True(t, spy.CalledWith("Method 1 called from Method 2"))
+	assert.Equal(t, []interface{}{"first call"}, spy.ArgsFor(0))
+	assert.True(t, spy.NthCall(0).Returned("This is my Method 1 being called! Input: first call"))
+}
+
+// SCENARIO 6: a MockChan lets the test script the channel myFuncVar3 hands
+// back, deterministically unblocking ChannelProgramFlow's receive loop.
+func TestMockChan(t *testing.T) {
+	wrappedWithMock := ServiceMock{Service: NewService()}
+
+	mc := varmock.NewMockChan[error](0)
+	wrappedWithMock.myFuncVar3 = func() <-chan error { return mc.Chan() }
+
+	done := make(chan error, 1)
+	go func() { done <- ChannelProgramFlow(wrappedWithMock.Service) }()
+
+	mc.Send(nil)
+	mc.ExpectReceive(t, time.Second)
+
+	mc.Send(assert.AnError)
+	mc.ExpectReceive(t, time.Second)
+
+	select {
+	case got := <-done:
+		assert.Equal(t, assert.AnError, got)
+	case <-time.After(time.Second):
+		t.Fatal("ChannelProgramFlow did not return after receiving the injected error")
+	}
+}
+
+// SCENARIO 7: a Recorder aggregates several Spies by name, so a test helper
+// can assert call counts across a whole mock struct's fields at once instead
+// of holding each Spy in its own local variable.
+func TestRecorder(t *testing.T) {
+	wrappedWithMock := ServiceMock{Service: NewService()}
+
+	var rec varmock.Recorder
+
+	spy1 := varmock.NewSpy(wrappedWithMock.myMethod1Impl)
+	wrappedWithMock.myFuncVar1 = spy1.Fn()
+	rec.Register("myFuncVar1", spy1)
+
+	spy2 := varmock.NewSpy(wrappedWithMock.myMethod2Impl)
+	wrappedWithMock.myFuncVar2 = spy2.Fn()
+	rec.Register("myFuncVar2", spy2)
+
+	NormalProgramFlow(wrappedWithMock.Service)
+
+	gotSpy1, ok := rec.Spy("myFuncVar1").(*varmock.Spy[func(string) string])
+	assert.True(t, ok)
+	assert.Equal(t, 2, gotSpy1.CallCount())
+
+	gotSpy2, ok := rec.Spy("myFuncVar2").(*varmock.Spy[func()])
+	assert.True(t, ok)
+	assert.Equal(t, 1, gotSpy2.CallCount())
+
+	assert.Nil(t, rec.Spy("neverRegistered"))
+}
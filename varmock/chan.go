@@ -0,0 +1,117 @@
+package varmock
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ChanFuncVar names the shape of a function-variable field whose method
+// returns a receive-only channel, e.g. `myFuncVar3 varmock.ChanFuncVar[error]`
+// instead of the equivalent `func() <-chan error`. Using the named type is
+// optional; MockChan works with either.
+type ChanFuncVar[T any] func() <-chan T
+
+// MockChan is a controllable stand-in for a method's returned <-chan T. It
+// lets a test drive channel traffic step-by-step instead of racing the SUT's
+// own producer goroutine, the gap where tests otherwise block forever on
+// something like <-svc.Done() with no way to script what arrives and when.
+//
+// Chan() returns the same underlying channel on every call, so MockChan also
+// backs a method the SUT invokes more than once (fan-in): every invocation
+// receives from that one channel, so each value sent is received by exactly
+// one of them.
+type MockChan[T any] struct {
+	ch chan T
+
+	mu        sync.Mutex
+	closed    bool
+	delivered int
+	checked   int
+}
+
+// NewMockChan creates a MockChan with the given channel buffer (0 for
+// unbuffered, matching the common `chan error` pattern, where a completed
+// Send already proves the SUT received the value).
+func NewMockChan[T any](buffer int) *MockChan[T] {
+	return &MockChan[T]{ch: make(chan T, buffer)}
+}
+
+// Chan returns the <-chan T to assign into the function-variable field being
+// mocked, e.g. `wrappedWithMock.myFuncVar3 = func() <-chan error { return mc.Chan() }`.
+func (mc *MockChan[T]) Chan() <-chan T {
+	return mc.ch
+}
+
+// Send pushes v onto the channel, blocking until the SUT receives it (for an
+// unbuffered MockChan) or room is available in the buffer.
+func (mc *MockChan[T]) Send(v T) {
+	mc.ch <- v
+	mc.mu.Lock()
+	mc.delivered++
+	mc.mu.Unlock()
+}
+
+// Close closes the channel so a range/receive loop in the SUT unblocks. Safe
+// to call more than once.
+func (mc *MockChan[T]) Close() {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	if mc.closed {
+		return
+	}
+	close(mc.ch)
+	mc.closed = true
+}
+
+// WithContext closes the channel once ctx is done, releasing a SUT goroutine
+// blocked on a receive instead of leaking it past the end of the test.
+func (mc *MockChan[T]) WithContext(ctx context.Context) *MockChan[T] {
+	go func() {
+		<-ctx.Done()
+		mc.Close()
+	}()
+	return mc
+}
+
+// ExpectReceive fails t if one more value isn't delivered within timeout.
+// Repeated calls each wait for the next delivery, so a sequence of
+// ExpectReceive calls asserts the SUT drained that many values in order.
+func (mc *MockChan[T]) ExpectReceive(t *testing.T, timeout time.Duration) {
+	t.Helper()
+	mc.ExpectReceiveN(t, 1, timeout)
+}
+
+// ExpectReceiveN fails t if n more values aren't delivered within timeout.
+func (mc *MockChan[T]) ExpectReceiveN(t *testing.T, n int, timeout time.Duration) {
+	t.Helper()
+
+	mc.mu.Lock()
+	target := mc.checked + n
+	mc.mu.Unlock()
+
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		mc.mu.Lock()
+		delivered := mc.delivered
+		mc.mu.Unlock()
+
+		if delivered >= target {
+			mc.mu.Lock()
+			mc.checked = target
+			mc.mu.Unlock()
+			return
+		}
+
+		select {
+		case <-deadline:
+			t.Fatalf("varmock: timed out after %s waiting for %d more delivered value(s), saw %d", timeout, n, delivered-(target-n))
+			return
+		case <-ticker.C:
+		}
+	}
+}
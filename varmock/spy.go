@@ -0,0 +1,153 @@
+package varmock
+
+import (
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Call is a single invocation recorded by a Spy.
+type Call struct {
+	Args        []interface{}
+	Returns     []interface{}
+	At          time.Time
+	GoroutineID int64
+}
+
+// Returned reports whether this call's return values equal want.
+func (c Call) Returned(want ...interface{}) bool {
+	return reflect.DeepEqual(c.Returns, want)
+}
+
+// Spy wraps a function-variable field of type T and transparently records
+// every call made through it: arguments, return values, timestamp, and
+// goroutine id. It replaces the ad-hoc `count int` / `capturedReturns
+// []string` fields ServiceMock otherwise hand-rolls around a single field.
+type Spy[T any] struct {
+	mu    sync.Mutex
+	calls []Call
+	orig  T
+}
+
+// NewSpy wraps fn, typically the -Impl a function-variable field already
+// holds, for recording. Assign Fn() back into the field:
+//
+//	spy := varmock.NewSpy(wrappedWithMock.myMethod1Impl)
+//	wrappedWithMock.myFuncVar1 = spy.Fn()
+func NewSpy[T any](fn T) *Spy[T] {
+	return &Spy[T]{orig: fn}
+}
+
+// Fn returns the instrumented function to assign into the field being spied
+// on. Every call to it runs the original fn and records the result before
+// returning it, so program behavior is unchanged.
+func (s *Spy[T]) Fn() T {
+	origVal := reflect.ValueOf(s.orig)
+	fnType := origVal.Type()
+
+	wrapped := reflect.MakeFunc(fnType, func(args []reflect.Value) []reflect.Value {
+		ret := origVal.Call(args)
+
+		s.mu.Lock()
+		s.calls = append(s.calls, Call{
+			Args:        toInterfaces(args),
+			Returns:     toInterfaces(ret),
+			At:          time.Now(),
+			GoroutineID: goroutineID(),
+		})
+		s.mu.Unlock()
+
+		return ret
+	})
+
+	out := reflect.New(fnType).Elem()
+	out.Set(wrapped)
+	return out.Interface().(T)
+}
+
+// Calls returns every recorded invocation, in call order.
+func (s *Spy[T]) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Call, len(s.calls))
+	copy(out, s.calls)
+	return out
+}
+
+// CallCount returns the number of recorded invocations.
+func (s *Spy[T]) CallCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.calls)
+}
+
+// ArgsFor returns the arguments of the i-th recorded call.
+func (s *Spy[T]) ArgsFor(i int) []interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[i].Args
+}
+
+// NthCall returns the i-th recorded call.
+func (s *Spy[T]) NthCall(i int) Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[i]
+}
+
+// CalledWith reports whether any recorded call's arguments equal want.
+func (s *Spy[T]) CalledWith(want ...interface{}) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.calls {
+		if reflect.DeepEqual(c.Args, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// Recorder aggregates named Spy instances so every function-variable field
+// on a whole mock struct can be inspected together, e.g. from a test helper
+// that asserts call counts across several fields at once. Spies are stored
+// as interface{} since their Spy[T] type varies per field's signature;
+// callers type-assert back to the concrete Spy[T] they registered.
+type Recorder struct {
+	mu    sync.Mutex
+	spies map[string]interface{}
+}
+
+// Register associates name (typically the field name) with spy for later
+// lookup via Spy.
+func (r *Recorder) Register(name string, spy interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.spies == nil {
+		r.spies = map[string]interface{}{}
+	}
+	r.spies[name] = spy
+}
+
+// Spy returns the spy registered under name, or nil if none was.
+func (r *Recorder) Spy(name string) interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.spies[name]
+}
+
+// goroutineID extracts the calling goroutine's id from its stack trace
+// header ("goroutine 7 [running]: ..."), the cheapest way to obtain it
+// without runtime internals.
+func goroutineID() int64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	fields := strings.Fields(strings.TrimPrefix(string(buf[:n]), "goroutine "))
+	if len(fields) == 0 {
+		return 0
+	}
+	id, _ := strconv.ParseInt(fields[0], 10, 64)
+	return id
+}
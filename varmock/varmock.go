@@ -0,0 +1,220 @@
+// Package varmock layers reusable, reflection-based helpers on top of the
+// function-variable mocking pattern demonstrated in demo_mocks: instead of
+// `m.On("myMethod1Instrumented", "first call").Return(...)`, tests register
+// handlers directly against the function-variable field being mocked, so a
+// rename of the underlying method is caught by the compiler rather than by a
+// silently-unmatched string.
+package varmock
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Dispatcher adds argument-driven dynamic returns to an embedded
+// testify/mock.Mock. Embed it in place of mock.Mock (it promotes On, Called,
+// AssertExpectations, etc. unchanged) and call OnFunc to mock a
+// function-variable field instead of a string method name.
+type Dispatcher struct {
+	mock.Mock
+
+	// Wg tracks calls in flight across every function variable registered
+	// via OnFunc: each call Add(1)s on entry and Done()s on exit, so tests
+	// can Wg.Wait() to drain calls the SUT made from a goroutine. WaitFor
+	// below wraps the common case of waiting for a specific count.
+	Wg sync.WaitGroup
+
+	mu        sync.Mutex
+	stubs     map[uintptr][]*binding
+	calls     map[uintptr]int
+	originals map[uintptr]reflect.Value
+}
+
+// binding pairs an optional argument matcher with the handler to run when it
+// matches. A nil matcher always matches.
+type binding struct {
+	matcher reflect.Value
+	handler reflect.Value
+}
+
+func (b *binding) matches(args []reflect.Value) bool {
+	if !b.matcher.IsValid() {
+		return true
+	}
+	return b.matcher.Call(args)[0].Bool()
+}
+
+// Stub collects the WhenArgs/ReturnFn pair for a single OnFunc registration.
+type Stub struct {
+	d       *Dispatcher
+	key     uintptr
+	fnType  reflect.Type
+	matcher reflect.Value
+}
+
+// OnFunc registers funcVar, a pointer to a struct field of type
+// func(...)..., e.g. &wrappedWithMock.myFuncVar1, for dispatch. The field is
+// replaced with a trampoline that consults the bindings registered through
+// the returned Stub's WhenArgs/ReturnFn, in registration order, and falls
+// through to whatever funcVar held at the time of the *first* OnFunc call on
+// this field (the method's -Impl, by the convention in demo_mocks) if none
+// match.
+//
+// OnFunc may be called more than once against the same field -- the only way
+// to register a second WhenArgs/ReturnFn pair, since ReturnFn returns a
+// *mock.Call rather than a *Stub. Only the first call installs the
+// trampoline and snapshots the original; later calls just append another
+// binding and hand back a fresh *Stub, so the fallthrough keeps reaching the
+// true -Impl instead of a previously-installed trampoline.
+func (d *Dispatcher) OnFunc(funcVar interface{}) *Stub {
+	fv := reflect.ValueOf(funcVar)
+	if fv.Kind() != reflect.Ptr || fv.Elem().Kind() != reflect.Func {
+		panic("varmock: OnFunc requires a pointer to a func field")
+	}
+
+	field := fv.Elem()
+	key := fv.Pointer()
+	fnType := field.Type()
+
+	d.mu.Lock()
+	if d.stubs == nil {
+		d.stubs = map[uintptr][]*binding{}
+	}
+	if d.originals == nil {
+		d.originals = map[uintptr]reflect.Value{}
+	}
+	_, alreadyWrapped := d.originals[key]
+	if !alreadyWrapped {
+		// original must be a detached copy of the func field's current value:
+		// field itself stays bound to the field's address, so if we kept
+		// aliasing it here the fallthrough below would call whatever field
+		// holds *now* -- the trampoline we're about to install, once
+		// field.Set runs -- and recurse into itself forever.
+		d.originals[key] = reflect.ValueOf(field.Interface())
+	}
+	d.mu.Unlock()
+
+	if !alreadyWrapped {
+		field.Set(reflect.MakeFunc(fnType, func(args []reflect.Value) []reflect.Value {
+			d.Wg.Add(1)
+			defer d.Wg.Done()
+
+			d.mu.Lock()
+			bindings := d.stubs[key]
+			original := d.originals[key]
+			d.mu.Unlock()
+
+			var matched bool
+			var ret []reflect.Value
+			for _, b := range bindings {
+				if b.matches(args) {
+					ret = b.handler.Call(args)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				ret = original.Call(args)
+			}
+
+			d.mu.Lock()
+			if d.calls == nil {
+				d.calls = map[uintptr]int{}
+			}
+			d.calls[key]++
+			d.mu.Unlock()
+
+			return ret
+		}))
+	}
+
+	return &Stub{d: d, key: key, fnType: fnType}
+}
+
+// WaitFor blocks until funcVar, previously registered via OnFunc, has
+// completed n calls, or returns an error once timeout elapses. Use it when
+// the SUT invokes the mocked function from a goroutine instead of the
+// caller's own call stack, where a plain assertion immediately after
+// entering the SUT would otherwise race the call.
+func (d *Dispatcher) WaitFor(funcVar interface{}, n int, timeout time.Duration) error {
+	key := reflect.ValueOf(funcVar).Pointer()
+
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		d.mu.Lock()
+		count := d.calls[key]
+		d.mu.Unlock()
+		if count >= n {
+			return nil
+		}
+
+		select {
+		case <-deadline:
+			return fmt.Errorf("varmock: timed out after %s waiting for %d call(s), saw %d", timeout, n, count)
+		case <-ticker.C:
+		}
+	}
+}
+
+// WhenArgs narrows the stub to calls whose arguments satisfy matcher, a func
+// with the mocked field's parameter types and a single bool result. Omit it
+// to match every call.
+func (s *Stub) WhenArgs(matcher interface{}) *Stub {
+	mv := reflect.ValueOf(matcher)
+	if mv.Kind() != reflect.Func {
+		panic("varmock: WhenArgs requires a func")
+	}
+	s.matcher = mv
+	return s
+}
+
+// ReturnFn registers handler, a func matching the mocked field's exact
+// signature, to run when this stub's matcher (or, absent WhenArgs, any call)
+// hits. Every matching call is also recorded against the dispatcher's
+// embedded mock.Mock under a synthetic method name keyed to funcVar's
+// address, so the returned *mock.Call can still be chained with
+// .Once()/.Times(n), and AssertExpectations still fails unmet expectations.
+func (s *Stub) ReturnFn(handler interface{}) *mock.Call {
+	hv := reflect.ValueOf(handler)
+	if hv.Kind() != reflect.Func || hv.Type() != s.fnType {
+		panic("varmock: ReturnFn requires a func matching the mocked field's signature")
+	}
+
+	name := fmt.Sprintf("OnFunc@%#x", s.key)
+	wrapped := reflect.MakeFunc(s.fnType, func(args []reflect.Value) []reflect.Value {
+		// Called infers the expectation's method name from the caller via
+		// runtime.Caller, which here would resolve to this closure rather
+		// than name; MethodCalled takes the name explicitly instead.
+		s.d.MethodCalled(name, toInterfaces(args)...)
+		return hv.Call(args)
+	})
+
+	s.d.mu.Lock()
+	s.d.stubs[s.key] = append(s.d.stubs[s.key], &binding{matcher: s.matcher, handler: wrapped})
+	s.d.mu.Unlock()
+
+	return s.d.On(name, anyArgs(s.fnType)...)
+}
+
+func anyArgs(fnType reflect.Type) []interface{} {
+	args := make([]interface{}, fnType.NumIn())
+	for i := range args {
+		args[i] = mock.Anything
+	}
+	return args
+}
+
+func toInterfaces(args []reflect.Value) []interface{} {
+	out := make([]interface{}, len(args))
+	for i, a := range args {
+		out[i] = a.Interface()
+	}
+	return out
+}